@@ -0,0 +1,207 @@
+package tm1637
+
+import (
+	"sync"
+	"time"
+)
+
+// Frame is a single animation frame: one segment byte per grid (up to
+// MaxGrids) and how long to hold it before advancing to the next frame.
+type Frame struct {
+	Segs [MaxGrids]byte
+	Hold time.Duration
+}
+
+// Animator plays sequences of Frames on a TM1637 from a background
+// goroutine, so callers don't have to block their own goroutine in
+// time.Sleep between frames (the pattern the scroll and brightness-sweep
+// examples in cmd/main.go currently use). Writes are diffed against the
+// last frame shown, and only the grids that changed are sent through
+// WriteSegmentsAt (the fixed-address command for a lone changed grid, an
+// auto-increment write for a run of them), keeping the bit-banging off the
+// caller's hot path.
+type Animator struct {
+	tm *TM1637
+
+	mu     sync.Mutex
+	stopCh chan struct{}
+	done   chan struct{}
+	last   [MaxGrids]byte
+	have   bool
+}
+
+// NewAnimator wraps tm with an Animator.
+func NewAnimator(tm *TM1637) *Animator {
+	return &Animator{tm: tm}
+}
+
+// Play stops any animation already running and plays frames in a background
+// goroutine, starting immediately. If loop is true, frames repeat until
+// Stop is called; otherwise playback ends after the last frame's Hold
+// elapses.
+func (a *Animator) Play(frames []Frame, loop bool) {
+	stopCh, done := a.start()
+
+	go func() {
+		defer close(done)
+		for {
+			for _, f := range frames {
+				if a.writeFrame(f) != nil {
+					return
+				}
+				select {
+				case <-stopCh:
+					return
+				case <-time.After(f.Hold):
+				}
+			}
+			if !loop {
+				return
+			}
+		}
+	}()
+}
+
+// Enqueue writes a single frame immediately, using the same diff-based
+// writer as Play. It's meant for callers driving their own animation timing
+// (e.g. a counter that only ticks once a second) who still want the
+// fixed-address, changed-grids-only writes.
+func (a *Animator) Enqueue(f Frame) error {
+	return a.writeFrame(f)
+}
+
+// FadeBrightness smoothly ramps the display brightness from `from` to `to`
+// over dur by rapidly resending display-control commands, rather than
+// rewriting segment data. Like Play, it stops any animation already running
+// and runs in a background goroutine, returning immediately.
+func (a *Animator) FadeBrightness(from, to byte, dur time.Duration) {
+	stopCh, done := a.start()
+
+	go func() {
+		defer close(done)
+
+		steps := int(to) - int(from)
+		direction := 1
+		if steps < 0 {
+			steps = -steps
+			direction = -1
+		}
+		if steps == 0 {
+			a.tm.SetBrightness(from)
+			return
+		}
+		step := dur / time.Duration(steps)
+
+		for level := int(from); ; level += direction {
+			if err := a.tm.SetBrightness(byte(level)); err != nil {
+				return
+			}
+			if level == int(to) {
+				return
+			}
+			select {
+			case <-stopCh:
+				return
+			case <-time.After(step):
+			}
+		}
+	}()
+}
+
+// Stop halts any animation started by Play or FadeBrightness and waits for
+// its goroutine to exit.
+func (a *Animator) Stop() {
+	a.mu.Lock()
+	stopCh := a.stopCh
+	done := a.done
+	a.stopCh = nil
+	a.done = nil
+	a.mu.Unlock()
+
+	if stopCh == nil {
+		return
+	}
+	close(stopCh)
+	<-done
+}
+
+// start stops any animation already running and installs a fresh stop/done
+// pair for the caller's goroutine.
+func (a *Animator) start() (stopCh, done chan struct{}) {
+	a.Stop()
+
+	stopCh = make(chan struct{})
+	done = make(chan struct{})
+
+	a.mu.Lock()
+	a.stopCh = stopCh
+	a.done = done
+	a.mu.Unlock()
+
+	return stopCh, done
+}
+
+// writeFrame pushes only the grids that changed since the last frame shown,
+// batching each contiguous run of changed grids into one WriteSegmentsAt
+// call.
+func (a *Animator) writeFrame(f Frame) error {
+	n := a.tm.NumGrids()
+
+	a.mu.Lock()
+	last := a.last
+	have := a.have
+	a.mu.Unlock()
+
+	for i := 0; i < n; {
+		if have && f.Segs[i] == last[i] {
+			i++
+			continue
+		}
+		j := i
+		for j < n && (!have || f.Segs[j] != last[j]) {
+			j++
+		}
+		if err := a.tm.WriteSegmentsAt(byte(i), f.Segs[i:j]); err != nil {
+			return err
+		}
+		i = j
+	}
+
+	a.mu.Lock()
+	a.last = f.Segs
+	a.have = true
+	a.mu.Unlock()
+
+	return nil
+}
+
+// Spinner returns a looping single-digit "loading" animation: the six
+// segments around digit 0 light up one at a time in sequence.
+func Spinner() []Frame {
+	order := []byte{0x01, 0x02, 0x04, 0x08, 0x10, 0x20} // A, B, C, D, E, F
+	frames := make([]Frame, len(order))
+	for i, seg := range order {
+		frames[i].Segs[0] = seg
+		frames[i].Hold = 100 * time.Millisecond
+	}
+	return frames
+}
+
+// Bounce returns a looping animation where a single middle-bar (G) segment
+// bounces left and right across NumDigits grids.
+func Bounce() []Frame {
+	const seg = 0x40 // G segment
+
+	frames := make([]Frame, 0, 2*(NumDigits-1))
+	for i := 0; i < NumDigits; i++ {
+		f := Frame{Hold: 100 * time.Millisecond}
+		f.Segs[i] = seg
+		frames = append(frames, f)
+	}
+	for i := NumDigits - 2; i > 0; i-- {
+		f := Frame{Hold: 100 * time.Millisecond}
+		f.Segs[i] = seg
+		frames = append(frames, f)
+	}
+	return frames
+}
@@ -0,0 +1,145 @@
+package tm1637
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CharacterDisplay is a higher-level façade over TM1637, adding printf-style
+// formatting, a scrolling marquee, a virtual write cursor, and user-defined
+// glyphs. It mirrors the character_display.go layer built on top of the
+// HD44780 driver: TM1637 stays a thin protocol driver, and this type carries
+// the text-oriented conveniences on top of it.
+type CharacterDisplay struct {
+	tm *TM1637
+
+	mu     sync.Mutex
+	cursor int
+	glyphs map[rune]byte
+}
+
+// NewCharacterDisplay wraps tm with a CharacterDisplay.
+func NewCharacterDisplay(tm *TM1637) *CharacterDisplay {
+	return &CharacterDisplay{
+		tm:     tm,
+		glyphs: make(map[rune]byte),
+	}
+}
+
+// DefineGlyph registers a custom 7-segment pattern under name. name must be
+// exactly one rune: that rune is the placeholder used to embed the glyph in
+// strings passed to Printf/Message/Scroll, the same way HD44780 custom
+// characters are referenced by the code point they were defined under.
+// Defining a glyph under a rune that already has a built-in mapping
+// overrides it for this CharacterDisplay, except for '.', which is always
+// fused into the preceding grid's decimal point (see renderSegments).
+func (c *CharacterDisplay) DefineGlyph(name string, segs byte) error {
+	runes := []rune(name)
+	if len(runes) != 1 {
+		return fmt.Errorf("tm1637: glyph name %q must be exactly one rune", name)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.glyphs[runes[0]] = segs
+	return nil
+}
+
+// segmentFor returns the segment pattern for r, preferring a glyph defined
+// via DefineGlyph over TM1637's built-in segment map.
+func (c *CharacterDisplay) segmentFor(r rune) byte {
+	c.mu.Lock()
+	seg, ok := c.glyphs[r]
+	c.mu.Unlock()
+	if ok {
+		return seg
+	}
+	return c.tm.SegmentFor(r)
+}
+
+// renderSegments converts s into one segment byte per visible grid. Runs of
+// '.' are fused into the decimal point bit of the preceding grid instead of
+// consuming a grid of their own, so e.g. "3.14" renders across 3 grids, not
+// 4. A leading '.' (nothing to fuse into yet) is dropped.
+func (c *CharacterDisplay) renderSegments(s string) []byte {
+	return fuseDotSegments(s, c.segmentFor)
+}
+
+// Message displays s across the full width of the display, space-padding or
+// truncating it to fit. See renderSegments for how '.' is packed.
+func (c *CharacterDisplay) Message(s string) error {
+	width := c.tm.NumGrids()
+	segs := c.renderSegments(s)
+
+	if len(segs) < width {
+		blank := c.segmentFor(' ')
+		for len(segs) < width {
+			segs = append(segs, blank)
+		}
+	} else if len(segs) > width {
+		segs = segs[:width]
+	}
+
+	return c.tm.WriteSegmentsAt(0, segs)
+}
+
+// Printf formats according to format and displays the result via Message.
+func (c *CharacterDisplay) Printf(format string, args ...interface{}) error {
+	return c.Message(fmt.Sprintf(format, args...))
+}
+
+// Scroll runs s as a marquee: starting off-screen, it shifts the visible
+// window by one rune every step until ctx is canceled. It starts a
+// background goroutine and returns immediately; cancel ctx (or let it time
+// out) to stop the animation cleanly.
+func (c *CharacterDisplay) Scroll(s string, step time.Duration, ctx context.Context) {
+	width := c.tm.NumGrids()
+	pad := strings.Repeat(" ", width)
+	runes := []rune(pad + s + pad)
+
+	go func() {
+		ticker := time.NewTicker(step)
+		defer ticker.Stop()
+
+		for {
+			for i := 0; i <= len(runes)-width; i++ {
+				if err := c.Message(string(runes[i : i+width])); err != nil {
+					return
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}
+	}()
+}
+
+// SetCursor moves the write cursor used by WriteChar to pos, a 0-based grid
+// index.
+func (c *CharacterDisplay) SetCursor(pos int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cursor = pos
+}
+
+// Home resets the write cursor to the first grid.
+func (c *CharacterDisplay) Home() {
+	c.SetCursor(0)
+}
+
+// WriteChar writes r's segment pattern at the current cursor position and
+// advances the cursor by one grid, without touching any other grid.
+func (c *CharacterDisplay) WriteChar(r rune) error {
+	c.mu.Lock()
+	pos := c.cursor
+	c.cursor++
+	c.mu.Unlock()
+
+	return c.tm.WriteSegmentsAt(byte(pos), []byte{c.segmentFor(r)})
+}
@@ -5,13 +5,7 @@ import (
 	"log"
 	"time"
 
-	// Assuming your tm1637 library is in a 'tm1637' directory
-	// relative to your project's go.mod file, or you've published it
-	// and can import it via its module path.
-	// For local development, if main.go and tm1637/tm1637.go are in the same parent directory:
-	// go mod init my_project_name
-	// then import "my_project_name/tm1637"
-	tm1637 "github.com/rpi-tm1637" // IMPORTANT: Replace 'your_module_path' with your actual module path
+	tm1637 "github.com/talkkonnect/rpi-tm1637"
 )
 
 func main() {
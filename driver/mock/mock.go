@@ -0,0 +1,64 @@
+// Package mock implements tm1637.PinDriver entirely in memory, with no
+// hardware dependency, so the rest of the library can be exercised in unit
+// tests or on non-Raspberry-Pi hosts.
+package mock
+
+// Driver is a tm1637.PinDriver that records every pin transition instead of
+// driving real hardware. By default it acknowledges every byte written (as
+// a real TM1637 would); set NAck to true to simulate a missing/unresponsive
+// display.
+type Driver struct {
+	// Clk and Dio hold the last value written to each line.
+	Clk, Dio bool
+	// DioIsInput is true while DIO has been switched to input mode (i.e.
+	// between a DioInput and the following DioOutput call).
+	DioIsInput bool
+	// NAck, when true, makes ReadDio report a NACK instead of an ACK.
+	NAck bool
+
+	// Writes records every WriteClk/WriteDio call, in order, for assertions.
+	Writes []PinWrite
+}
+
+// PinWrite is a single recorded pin transition.
+type PinWrite struct {
+	Pin  string // "clk" or "dio"
+	High bool
+}
+
+// New returns a Driver ready to use.
+func New() *Driver {
+	return &Driver{}
+}
+
+// WriteClk implements tm1637.PinDriver.
+func (d *Driver) WriteClk(high bool) {
+	d.Clk = high
+	d.Writes = append(d.Writes, PinWrite{Pin: "clk", High: high})
+}
+
+// WriteDio implements tm1637.PinDriver.
+func (d *Driver) WriteDio(high bool) {
+	d.Dio = high
+	d.Writes = append(d.Writes, PinWrite{Pin: "dio", High: high})
+}
+
+// ReadDio implements tm1637.PinDriver. It reports an ACK (low) unless NAck
+// is set.
+func (d *Driver) ReadDio() bool {
+	return d.NAck
+}
+
+// DioInput implements tm1637.PinDriver.
+func (d *Driver) DioInput(pullUp bool) {
+	d.DioIsInput = true
+}
+
+// DioOutput implements tm1637.PinDriver.
+func (d *Driver) DioOutput() {
+	d.DioIsInput = false
+}
+
+// BitDelay implements tm1637.PinDriver. It is a no-op: tests don't need to
+// wait on real microsecond-scale GPIO timing.
+func (d *Driver) BitDelay() {}
@@ -0,0 +1,96 @@
+// Package rpio implements tm1637.PinDriver on top of github.com/stianeikeland/go-rpio,
+// the direct /dev/gpiomem backend this library used before the driver interface
+// was introduced. It is the default driver for Raspberry Pi boards.
+package rpio
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stianeikeland/go-rpio/v4"
+)
+
+// DefaultBitDelay is the communication delay between signal transitions.
+// TM1637 only requires a few microseconds; this value matches what the
+// library has always used.
+const DefaultBitDelay = 5 * time.Microsecond
+
+// Driver drives a TM1637 over two go-rpio pins.
+type Driver struct {
+	clk   rpio.Pin
+	dio   rpio.Pin
+	delay time.Duration
+}
+
+// Open maps GPIO memory via go-rpio and returns a Driver for the given BCM
+// CLK/DIO pin numbers. Call Close when done to release the mapping.
+func Open(clkPinNumber, dioPinNumber int) (*Driver, error) {
+	if err := rpio.Open(); err != nil {
+		return nil, fmt.Errorf("failed to open rpio: %w", err)
+	}
+
+	d := &Driver{
+		clk:   rpio.Pin(clkPinNumber),
+		dio:   rpio.Pin(dioPinNumber),
+		delay: DefaultBitDelay,
+	}
+
+	d.clk.Output()
+	d.clk.Low()
+	d.dio.Output()
+	d.dio.Low()
+
+	return d, nil
+}
+
+// WriteClk implements tm1637.PinDriver.
+func (d *Driver) WriteClk(high bool) {
+	d.clk.Output()
+	if high {
+		d.clk.High()
+	} else {
+		d.clk.Low()
+	}
+}
+
+// WriteDio implements tm1637.PinDriver.
+func (d *Driver) WriteDio(high bool) {
+	d.dio.Output()
+	if high {
+		d.dio.High()
+	} else {
+		d.dio.Low()
+	}
+}
+
+// ReadDio implements tm1637.PinDriver.
+func (d *Driver) ReadDio() bool {
+	return d.dio.Read() == rpio.High
+}
+
+// DioInput implements tm1637.PinDriver.
+func (d *Driver) DioInput(pullUp bool) {
+	d.dio.Input()
+	if pullUp {
+		d.dio.PullUp()
+	}
+}
+
+// DioOutput implements tm1637.PinDriver.
+func (d *Driver) DioOutput() {
+	d.dio.Output()
+}
+
+// BitDelay implements tm1637.PinDriver.
+func (d *Driver) BitDelay() {
+	time.Sleep(d.delay)
+}
+
+// Close unmaps the GPIO memory opened by Open.
+//
+// Note: rpio.Close() affects the whole process, not just this Driver; if
+// other code in the same binary also uses go-rpio directly, avoid calling
+// this until everything is done with it.
+func (d *Driver) Close() {
+	rpio.Close()
+}
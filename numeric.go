@@ -0,0 +1,210 @@
+package tm1637
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fuseDotSegments converts s into one segment byte per visible grid, fusing
+// '.' runes into the decimal point bit of the preceding grid rather than
+// consuming a grid of their own. segFor resolves the pattern for a non-dot
+// rune; a leading '.' (nothing to fuse into yet) is dropped.
+func fuseDotSegments(s string, segFor func(rune) byte) []byte {
+	segs := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r == '.' {
+			if len(segs) > 0 {
+				segs[len(segs)-1] |= 0x80
+			}
+			continue
+		}
+		segs = append(segs, segFor(r))
+	}
+	return segs
+}
+
+// Radix selects the base DisplayInt renders a number in.
+type Radix int
+
+// Supported DisplayInt radixes.
+const (
+	Dec Radix = 10
+	Hex Radix = 16
+	Bin Radix = 2
+)
+
+// numOptions collects the settings applied by the NumOpt functions passed to
+// DisplayInt.
+type numOptions struct {
+	width     int // minimum digit count (not counting a sign); 0 means "just what's needed"
+	zeroPad   bool
+	alignLeft bool
+	showSign  bool
+	radix     Radix
+}
+
+// NumOpt configures DisplayInt's rendering of an integer.
+type NumOpt func(*numOptions)
+
+// WithWidth sets the minimum number of digits to render, not counting a
+// sign. Shorter numbers are padded with spaces, or with '0' if WithZeroPad
+// is also given.
+func WithWidth(n int) NumOpt {
+	return func(o *numOptions) { o.width = n }
+}
+
+// WithZeroPad pads up to the configured width with '0' instead of ' '.
+func WithZeroPad() NumOpt {
+	return func(o *numOptions) { o.zeroPad = true }
+}
+
+// WithLeftAlign left-aligns the rendered number within the display instead
+// of the default right alignment.
+func WithLeftAlign() NumOpt {
+	return func(o *numOptions) { o.alignLeft = true }
+}
+
+// WithSign reserves a leading column for the sign: '-' for negative
+// numbers, a blank column for non-negative ones. Without this option,
+// non-negative numbers don't reserve that column, so with WithLeftAlign the
+// digits shift by one column as a counter crosses zero. (7-segment displays
+// have no way to render a literal '+'.)
+func WithSign() NumOpt {
+	return func(o *numOptions) { o.showSign = true }
+}
+
+// WithRadix renders the integer in the given base instead of the default,
+// Dec.
+func WithRadix(r Radix) NumOpt {
+	return func(o *numOptions) { o.radix = r }
+}
+
+// DisplayInt renders n across the full display width (see NumGrids), right
+// aligned and in decimal by default. See WithWidth, WithZeroPad,
+// WithLeftAlign, WithSign and WithRadix to control padding, alignment, sign
+// and base.
+func (d *TM1637) DisplayInt(n int, opts ...NumOpt) error {
+	o := numOptions{radix: Dec}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	sign := ""
+	var abs uint64
+	if n < 0 {
+		sign = "-"
+		// Negating n directly would overflow for n == math.MinInt; this
+		// form stays within range by negating n+1 (always representable)
+		// and adding the 1 back after the unsigned conversion.
+		abs = uint64(-(n + 1)) + 1
+	} else {
+		abs = uint64(n)
+		if o.showSign {
+			sign = " "
+		}
+	}
+
+	digits := strconv.FormatUint(abs, int(o.radix))
+	if o.width > len(digits) {
+		pad := " "
+		if o.zeroPad {
+			pad = "0"
+		}
+		digits = strings.Repeat(pad, o.width-len(digits)) + digits
+	}
+
+	return d.displayAligned(sign+digits, o.alignLeft)
+}
+
+// renderedGridCount returns how many grids s will occupy once fuseDotSegments
+// fuses its '.' runs into the preceding grid.
+func renderedGridCount(s string) int {
+	return len(fuseDotSegments(s, func(rune) byte { return 0 }))
+}
+
+// DisplayFloat renders f with precision digits after the decimal point,
+// using the DP bit of the appropriate grid for the point rather than
+// consuming a grid of its own: DisplayFloat(3.14, 2) occupies 3 grids ('3',
+// '1', '4'), with the DP bit set on '3'. Like DisplayTemperature, it errors
+// rather than silently dropping a leading digit when the rendered value
+// doesn't fit the display's width.
+func (d *TM1637) DisplayFloat(f float64, precision int) error {
+	s := strconv.FormatFloat(f, 'f', precision, 64)
+
+	if n := renderedGridCount(s); n > d.NumGrids() {
+		return fmt.Errorf("tm1637: value %q doesn't fit %d grids", s, d.NumGrids())
+	}
+
+	return d.displayAligned(s, false)
+}
+
+// DisplayClock renders h:m as a 4-digit clock, e.g. "1234" for 12:34, with
+// the colon lit permanently or, if blinkColon is true, lit only while the
+// current Unix second is even (a common "flashing colon" clock convention). Hours and minutes are always shown zero-padded to 2
+// digits; h and m must each fit in 2 digits (0-99).
+func (d *TM1637) DisplayClock(h, m int, blinkColon bool) error {
+	if h < 0 || h > 99 || m < 0 || m > 99 {
+		return fmt.Errorf("tm1637: clock time %d:%d out of range", h, m)
+	}
+
+	d.SetColon(!blinkColon || (time.Now().Unix()%2 == 0))
+	return d.displayAligned(fmt.Sprintf("%02d%02d", h, m), false)
+}
+
+// DisplayTemperature renders c (in whatever unit the caller passes) followed
+// by the degree glyph and unit rune, e.g. DisplayTemperature(5.0, 'C') shows
+// "5.0" with a trailing "°C". A non-negative one-decimal reading with a
+// single integer digit needs 4 grids after '.' is fused into the preceding one, so it fits
+// the default NumDigits-grid display; two integer digits (e.g. 21.5, which
+// needs 5 grids: '2','1.','5','°','C') require a board built with
+// WithGrids(6) or wider. As with DisplayFloat, it errors rather than
+// silently dropping a leading digit when the rendered value doesn't fit the
+// display's width.
+func (d *TM1637) DisplayTemperature(c float64, unit rune) error {
+	value := strconv.FormatFloat(c, 'f', 1, 64)
+	s := value + "°" + string(unit)
+
+	if n := renderedGridCount(s); n > d.NumGrids() {
+		return fmt.Errorf("tm1637: temperature %q doesn't fit %d grids", s, d.NumGrids())
+	}
+
+	return d.displayAligned(s, false)
+}
+
+// displayAligned renders s via fuseDotSegments, pads or truncates it to the
+// display's full width, and writes it starting at grid 0. Truncation keeps
+// the trailing (least significant) grids; alignLeft pads on the right
+// instead of the left.
+func (d *TM1637) displayAligned(s string, alignLeft bool) error {
+	width := d.NumGrids()
+
+	d.mu.Lock()
+	segs := fuseDotSegments(s, d.segmentForRune)
+	blank := d.segmentForRune(' ')
+	colonEnabled := d.colonEnabled
+	d.mu.Unlock()
+
+	if len(segs) > width {
+		segs = segs[len(segs)-width:]
+	} else if len(segs) < width {
+		pad := make([]byte, width-len(segs))
+		for i := range pad {
+			pad[i] = blank
+		}
+		if alignLeft {
+			segs = append(segs, pad...)
+		} else {
+			segs = append(pad, segs...)
+		}
+	}
+
+	if colonEnabled && len(segs) > 1 {
+		// Same convention as DisplaySegments/DisplayCharacters: the colon is
+		// the dot segment of the 2nd grid.
+		segs[1] |= 0x80
+	}
+
+	return d.WriteSegmentsAt(0, segs)
+}
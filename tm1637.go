@@ -4,10 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"sync"
-	"time"
 	"unicode"
 
-	"github.com/stianeikeland/go-rpio/v4"
+	"github.com/talkkonnect/rpi-tm1637/driver/rpio"
 )
 
 // TM1637 commands
@@ -20,23 +19,73 @@ const (
 	MaxBrightness     byte = 7
 	DefaultBrightness byte = 2 // A moderate brightness level
 	NumDigits         int  = 4
+
+	// MaxGrids is the largest number of digit grids the TM1637 protocol
+	// supports (the address register only spans grids 0-5).
+	MaxGrids int = 6
 )
 
+// PinDriver abstracts the two-wire (CLK/DIO) bit-banging that the TM1637
+// protocol is built on, so the library does not have to depend on any
+// particular GPIO stack. Implementations live under driver/ (e.g.
+// driver/rpio for go-rpio, driver/mock for host-side tests).
+type PinDriver interface {
+	// WriteClk drives the CLK line high or low. The pin must already be (or
+	// be switched to) output mode.
+	WriteClk(high bool)
+	// WriteDio drives the DIO line high or low. The pin must already be (or
+	// be switched to) output mode.
+	WriteDio(high bool)
+	// ReadDio reads the current state of the DIO line. The pin must already
+	// be in input mode (see DioInput).
+	ReadDio() bool
+	// DioInput switches DIO to input mode, optionally enabling an internal
+	// pull-up, so the ACK bit driven by the TM1637 can be sampled.
+	DioInput(pullUp bool)
+	// DioOutput switches DIO back to output mode.
+	DioOutput()
+	// BitDelay blocks for whatever communication delay the driver needs
+	// between signal transitions (TM1637 requires a few microseconds).
+	BitDelay()
+}
 
-// TM1637 represents a TM1637 7-segment display driver using go-rpio.
+// TM1637 represents a TM1637 7-segment display driver.
 type TM1637 struct {
-	clkPin rpio.Pin
-	dioPin rpio.Pin
+	drv PinDriver
 
 	brightness   byte
 	colonEnabled bool
+	numGrids     int
+	// controlDirty is true when the display-control command (brightness/on
+	// state) hasn't been sent since it last changed (or since construction).
+	// Partial writes only pay for re-sending it when this is set, instead of
+	// on every tick.
+	controlDirty bool
 
-	mu    sync.Mutex
-	delay time.Duration // Communication delay, typically a few microseconds
+	mu sync.Mutex
 
 	digitToSegment map[rune]byte
 }
 
+// Option configures optional TM1637 parameters at construction time,
+// applied by New and NewWithDriver.
+type Option func(*TM1637)
+
+// WithGrids sets the number of digit grids the display has. TM1637 supports
+// up to MaxGrids (6); most common 4-digit modules don't need this option,
+// since NumDigits (4) is the default. Out-of-range values are clamped.
+func WithGrids(n int) Option {
+	return func(d *TM1637) {
+		if n < 1 {
+			n = 1
+		}
+		if n > MaxGrids {
+			n = MaxGrids
+		}
+		d.numGrids = n
+	}
+}
+
 // defaultSegmentMap provides a basic mapping from characters to 7-segment display codes.
 // Bit order: DP.G.F.E.D.C.B.A (MSB to LSB: bit7=DP, bit6=G, ..., bit0=A)
 var defaultSegmentMap = map[rune]byte{
@@ -45,40 +94,60 @@ var defaultSegmentMap = map[rune]byte{
 	'8': 0x7f, '9': 0x6f,
 	'a': 0x77, 'b': 0x7c, 'c': 0x39, 'd': 0x5e,
 	'e': 0x79, 'f': 0x71,
-	'g': 0x6f, // Often same as 9 or custom
+	'g': 0x6f,            // Often same as 9 or custom
 	'h': 0x76, 'i': 0x04, // Or 0x06 for '1'
 	'j': 0x1e, 'k': 0x76, // Similar to 'h'
 	'l': 0x38, 'm': 0x37, // Custom, two 'n's
 	'n': 0x54, 'o': 0x5c, 'p': 0x73, 'q': 0x67,
 	'r': 0x50, 's': 0x6d, // Same as '5'
 	't': 0x78, 'u': 0x3e, 'v': 0x3e, // Same as 'u'
-	'w': 0x7e, // Custom, two 'v's
-	'x': 0x76, // Similar to 'h'
+	'w': 0x7e,            // Custom, two 'v's
+	'x': 0x76,            // Similar to 'h'
 	'y': 0x6e, 'z': 0x5b, // Same as '2'
 	' ': 0x00, // Blank
 	'-': 0x40, // Minus
 	'_': 0x08, // Underscore (segment D)
 	'.': 0x80, // Dot (DP segment only) - special handling if used as char
-	'Â°': 0x63, // Degree symbol (segments A, B, G, F)
+	'°': 0x63, // Degree symbol (segments A, B, G, F)
 }
 
-// New initializes a TM1637 display driver using go-rpio.
-// clkPinNumber and dioPinNumber are the BCM GPIO pin numbers.
-func New(clkPinNumber, dioPinNumber int) (*TM1637, error) {
-	// Open and map memory to access gpio, check for errors
-	if err := rpio.Open(); err != nil {
-		return nil, fmt.Errorf("failed to open rpio: %w", err)
+// New initializes a TM1637 display driver on a Raspberry Pi, using the
+// driver/rpio backend (go-rpio) over the given BCM GPIO pin numbers. It is a
+// convenience wrapper around NewWithDriver for the common case; other GPIO
+// stacks should build their own PinDriver and call NewWithDriver directly.
+func New(clkPinNumber, dioPinNumber int, opts ...Option) (*TM1637, error) {
+	drv, err := rpio.Open(clkPinNumber, dioPinNumber)
+	if err != nil {
+		return nil, err
 	}
 
-	clk := rpio.Pin(clkPinNumber)
-	dio := rpio.Pin(dioPinNumber)
+	d := NewWithDriver(drv, opts...)
+
+	if err := d.SetBrightness(d.brightness); err != nil {
+		drv.Close()
+		return nil, fmt.Errorf("failed to set initial brightness: %w", err)
+	}
+	if err := d.Clear(); err != nil {
+		drv.Close()
+		return nil, fmt.Errorf("failed to clear display on init: %w", err)
+	}
+
+	return d, nil
+}
 
+// NewWithDriver builds a TM1637 on top of an already-initialized PinDriver.
+// Unlike New, it performs no I/O: the caller is responsible for bringing up
+// the underlying pins before use and for calling SetBrightness/Clear (or
+// relying on their own driver's reset behavior) if an initial state is
+// required. This is the extension point that lets the library run under any
+// GPIO stack (periph.io, gpiod/cdev, sysfs, or a mock for tests).
+func NewWithDriver(drv PinDriver, opts ...Option) *TM1637 {
 	d := &TM1637{
-		clkPin:         clk,
-		dioPin:         dio,
+		drv:            drv,
 		brightness:     DefaultBrightness,
 		colonEnabled:   false,
-		delay:          5 * time.Microsecond, // A common delay value
+		numGrids:       NumDigits,
+		controlDirty:   true,
 		digitToSegment: make(map[rune]byte),
 	}
 
@@ -90,23 +159,19 @@ func New(clkPinNumber, dioPinNumber int) (*TM1637, error) {
 		}
 	}
 
-	// Set initial pin modes (output, low)
-	d.clkPin.Output()
-	d.clkPin.Low()
-	d.dioPin.Output()
-	d.dioPin.Low()
-
-	if err := d.SetBrightness(d.brightness); err != nil {
-		// Attempt to close rpio even if setup fails partially
-		rpio.Close()
-		return nil, fmt.Errorf("failed to set initial brightness: %w", err)
-	}
-	if err := d.Clear(); err != nil {
-		rpio.Close()
-		return nil, fmt.Errorf("failed to clear display on init: %w", err)
+	for _, opt := range opts {
+		opt(d)
 	}
 
-	return d, nil
+	return d
+}
+
+// NumGrids returns the number of digit grids this display was configured
+// with (see WithGrids), the valid range for WriteSegmentsAt's pos argument.
+func (d *TM1637) NumGrids() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.numGrids
 }
 
 // sendCommand sends a single command byte to the display.
@@ -131,7 +196,11 @@ func (d *TM1637) SetBrightness(level byte) error {
 		level = MaxBrightness
 	}
 	d.brightness = level
-	return d.sendDisplayControl()
+	if err := d.sendDisplayControl(); err != nil {
+		return err
+	}
+	d.controlDirty = false
+	return nil
 }
 
 // sendDisplayControl sends the command to control display on/off and brightness.
@@ -175,6 +244,24 @@ func (d *TM1637) DisplaySegments(segmentsData [NumDigits]byte) error {
 	return d.displayRaw(dataToWrite)
 }
 
+// segmentForRune returns the configured 7-segment pattern for r, defaulting
+// to blank for unrecognized runes. The caller must hold d.mu.
+func (d *TM1637) segmentForRune(r rune) byte {
+	if segment, ok := d.digitToSegment[r]; ok {
+		return segment
+	}
+	return d.digitToSegment[' ']
+}
+
+// SegmentFor returns the configured 7-segment pattern for r, defaulting to
+// blank for unrecognized runes. It is exported for higher-level façades
+// (e.g. CharacterDisplay) that need to render arbitrary text themselves.
+func (d *TM1637) SegmentFor(r rune) byte {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.segmentForRune(r)
+}
+
 // DisplayCharacters displays up to 4 characters with optional dots.
 // chars: An array of 4 runes to display. Unrecognized chars become blank.
 // dots: An array of 4 booleans, true if the dot for the corresponding character should be lit.
@@ -186,12 +273,11 @@ func (d *TM1637) DisplayCharacters(chars [NumDigits]rune, dots [NumDigits]bool)
 	var segmentsData [NumDigits]byte
 	for i := 0; i < NumDigits; i++ {
 		charToDisplay := chars[i]
-		segment, ok := d.digitToSegment[charToDisplay]
+		var segment byte
 		if charToDisplay == '.' { // Special case: if rune is '.', it means only display the dot
 			segment = 0x00 // Start with blank
-			ok = true
-		} else if !ok {
-			segment = d.digitToSegment[' '] // Default to blank for unknown characters
+		} else {
+			segment = d.segmentForRune(charToDisplay)
 		}
 
 		if dots[i] || charToDisplay == '.' {
@@ -207,38 +293,86 @@ func (d *TM1637) DisplayCharacters(chars [NumDigits]rune, dots [NumDigits]bool)
 	return d.displayRaw(segmentsData)
 }
 
-// displayRaw sends the 4 segment bytes to the display.
+// displayRaw sends the 4 segment bytes to the display, starting at grid 0.
 // Assumes lock is held and dataToWrite has colon/dots already incorporated.
+// This is a full refresh, so it always re-asserts display control.
 func (d *TM1637) displayRaw(dataToWrite [NumDigits]byte) error {
-	// Data command: write data to display, auto increment address
+	return d.writeSegmentsLocked(0, dataToWrite[:], true)
+}
+
+// writeSegmentsLocked writes segs to the display starting at grid pos.
+// Assumes lock is held. A single-segment write uses the fixed-address
+// command (cmdDataFixedAddr) so only that grid is touched; a run of more
+// than one grid uses the auto-increment command (cmdDataAutoAddr), same as
+// the rest of the protocol.
+//
+// Display control (brightness/on state) is only re-sent when forceControl
+// is set (the full-refresh path) or when it's out of sync with the display
+// (controlDirty, e.g. nothing has been sent yet). Partial writes driving an
+// animation or counter tick otherwise skip it, since it never changes
+// between those writes.
+func (d *TM1637) writeSegmentsLocked(pos byte, segs []byte, forceControl bool) error {
+	if len(segs) == 0 {
+		return nil
+	}
+
+	dataCmd := cmdDataAutoAddr
+	if len(segs) == 1 {
+		dataCmd = cmdDataFixedAddr
+	}
+
 	d.start()
-	if err := d.writeByte(cmdDataAutoAddr); err != nil {
+	if err := d.writeByte(dataCmd); err != nil {
 		d.stop()
 		return fmt.Errorf("failed to send data command: %w", err)
 	}
 	d.stop()
 
-	// Address command: set start address to 0 (0xC0)
+	// Address command: set start address to the requested grid (0xC0 + pos)
 	d.start()
-	if err := d.writeByte(cmdAddrBase); err != nil {
+	if err := d.writeByte(cmdAddrBase | pos); err != nil {
 		d.stop()
 		return fmt.Errorf("failed to send address command: %w", err)
 	}
 
-	// Send the 4 data bytes for the segments
-	for i := 0; i < NumDigits; i++ {
-		if err := d.writeByte(dataToWrite[i]); err != nil {
+	for i, seg := range segs {
+		if err := d.writeByte(seg); err != nil {
 			d.stop()
-			return fmt.Errorf("failed to write segment data for digit %d: %w", i, err)
+			return fmt.Errorf("failed to write segment data for grid %d: %w", int(pos)+i, err)
 		}
 	}
 	d.stop()
 
-	// Re-assert display control (brightness/on state)
-	return d.sendDisplayControl()
+	if !forceControl && !d.controlDirty {
+		return nil
+	}
+
+	if err := d.sendDisplayControl(); err != nil {
+		return err
+	}
+	d.controlDirty = false
+	return nil
+}
+
+// WriteSegmentsAt writes segs to the display starting at grid pos, without
+// touching any other grid. This is cheaper than DisplaySegments/
+// DisplayCharacters for updates that only touch part of the display (a
+// single counter digit, one frame of an animation): a single-grid write
+// costs one fixed-address command instead of a full auto-increment refresh
+// of every grid.
+func (d *TM1637) WriteSegmentsAt(pos byte, segs []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if int(pos) < 0 || int(pos)+len(segs) > d.numGrids {
+		return fmt.Errorf("tm1637: position %d with %d segments exceeds %d grids", pos, len(segs), d.numGrids)
+	}
+
+	return d.writeSegmentsLocked(pos, segs, false)
 }
 
-// Close turns off the display and releases rpio resources.
+// Close turns off the display and releases the underlying driver, if it
+// supports being closed (e.g. driver/rpio unmaps GPIO memory).
 func (d *TM1637) Close() error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
@@ -250,13 +384,9 @@ func (d *TM1637) Close() error {
 	err := d.writeByte(cmd) // Attempt to send off command
 	d.stop()                // Ensure stop condition is sent
 
-	// Unmap memory ranges
-	// Note: rpio.Close() should be called once at the end of the application,
-	// not necessarily per device if multiple rpio devices are used.
-	// However, for a single device instance, it's fine here.
-	// If managing multiple rpio devices, rpio.Close() should be handled globally.
-	// For this library, we assume it's the primary user of rpio or needs to clean up.
-	rpio.Close() // This might be too aggressive if other parts of app use rpio.
+	if closer, ok := d.drv.(interface{ Close() }); ok {
+		closer.Close()
+	}
 
 	if err != nil {
 		return fmt.Errorf("failed to send display off command: %w", err)
@@ -268,89 +398,79 @@ func (d *TM1637) Close() error {
 
 // start sends the I2C-like start condition.
 func (d *TM1637) start() {
-	d.dioPin.Output() // Ensure DIO is output before changing state
-	d.dioPin.High()
-	time.Sleep(d.delay)
-	d.clkPin.Output() // Ensure CLK is output
-	d.clkPin.High()
-	time.Sleep(d.delay)
-
-	d.dioPin.Low()
-	time.Sleep(d.delay)
-
-	d.clkPin.Low()
-	time.Sleep(d.delay)
+	d.drv.DioOutput()
+	d.drv.WriteDio(true)
+	d.drv.BitDelay()
+	d.drv.WriteClk(true)
+	d.drv.BitDelay()
+
+	d.drv.WriteDio(false)
+	d.drv.BitDelay()
+
+	d.drv.WriteClk(false)
+	d.drv.BitDelay()
 }
 
 // stop sends the I2C-like stop condition.
 func (d *TM1637) stop() {
-	d.clkPin.Output() // Ensure CLK is output
-	d.clkPin.Low()
-	time.Sleep(d.delay)
-	d.dioPin.Output() // Ensure DIO is output
-	d.dioPin.Low()
-	time.Sleep(d.delay)
-
-	d.clkPin.High()
-	time.Sleep(d.delay)
-
-	d.dioPin.High()
-	time.Sleep(d.delay)
+	d.drv.WriteClk(false)
+	d.drv.BitDelay()
+	d.drv.DioOutput()
+	d.drv.WriteDio(false)
+	d.drv.BitDelay()
+
+	d.drv.WriteClk(true)
+	d.drv.BitDelay()
+
+	d.drv.WriteDio(true)
+	d.drv.BitDelay()
 }
 
 // writeByte sends one byte of data to the TM1637 and waits for ACK.
 // Data is sent LSB first.
 func (d *TM1637) writeByte(data byte) error {
-	d.clkPin.Output() // Ensure CLK is output
-	d.dioPin.Output() // Ensure DIO is output
+	d.drv.DioOutput()
 
 	// Send 8 bits, LSB first
 	for i := 0; i < 8; i++ {
-		d.clkPin.Low()
-		time.Sleep(d.delay)
+		d.drv.WriteClk(false)
+		d.drv.BitDelay()
 
-		if (data & 0x01) == 0x01 {
-			d.dioPin.High()
-		} else {
-			d.dioPin.Low()
-		}
-		time.Sleep(d.delay) // Data setup time
+		d.drv.WriteDio((data & 0x01) == 0x01)
+		d.drv.BitDelay() // Data setup time
 
-		d.clkPin.High() // Clock pulse
-		time.Sleep(d.delay)
+		d.drv.WriteClk(true) // Clock pulse
+		d.drv.BitDelay()
 
 		data >>= 1 // Next bit
 	}
 
 	// Wait for ACK:
 	// 1. CLK low
-	d.clkPin.Low()
-	time.Sleep(d.delay)
+	d.drv.WriteClk(false)
+	d.drv.BitDelay()
 
 	// 2. Set DIO to input with pull-up (TM1637 should pull it low for ACK)
-	d.dioPin.Input()
-	d.dioPin.PullUp() // Enable pull-up resistor
-	time.Sleep(d.delay)
+	d.drv.DioInput(true)
+	d.drv.BitDelay()
 
 	// 3. CLK high to clock out the ACK bit from TM1637
-	d.clkPin.High()
-	time.Sleep(d.delay)
+	d.drv.WriteClk(true)
+	d.drv.BitDelay()
 
 	// 4. Read ACK bit
-	ackState := d.dioPin.Read()
+	ackState := d.drv.ReadDio()
 
 	// 5. CLK low
-	d.clkPin.Low()
-	time.Sleep(d.delay)
+	d.drv.WriteClk(false)
+	d.drv.BitDelay()
 
 	// 6. Set DIO back to output, low state, ready for next transmission or stop
-	d.dioPin.Output()
-	d.dioPin.Low()
-	// time.Sleep(d.delay) // Optional small delay after restoring DIO
+	d.drv.DioOutput()
+	d.drv.WriteDio(false)
 
-	if ackState == rpio.High { // ACK should be low
+	if ackState { // ACK should be low
 		return errors.New("TM1637 NACK (no acknowledge)")
 	}
 	return nil
 }
-
@@ -0,0 +1,109 @@
+package tm1637_test
+
+import (
+	"testing"
+
+	tm1637 "github.com/talkkonnect/rpi-tm1637"
+	"github.com/talkkonnect/rpi-tm1637/driver/mock"
+)
+
+// protocolBytes replays a mock driver's recorded pin transitions as an
+// I2C-like observer would: a start condition is DIO falling while CLK is
+// held high, a stop condition is DIO rising while CLK is held high, and
+// each byte in between is 8 data bits plus an ack bit, sampled on CLK's
+// rising edges, LSB first.
+func protocolBytes(writes []mock.PinWrite) []byte {
+	var clk, dio bool
+	var inFrame bool
+	var bits []bool
+	var bytes []byte
+
+	for _, w := range writes {
+		switch w.Pin {
+		case "dio":
+			old := dio
+			dio = w.High
+			if clk {
+				switch {
+				case old && !dio:
+					inFrame = true
+					bits = nil
+				case !old && dio:
+					inFrame = false
+				}
+			}
+		case "clk":
+			oldClk := clk
+			clk = w.High
+			if inFrame && !oldClk && clk {
+				bits = append(bits, dio)
+				if len(bits) == 9 { // 8 data bits + 1 ack bit, LSB first
+					var b byte
+					for i := 0; i < 8; i++ {
+						if bits[i] {
+							b |= 1 << uint(i)
+						}
+					}
+					bytes = append(bytes, b)
+					bits = nil
+				}
+			}
+		}
+	}
+
+	return bytes
+}
+
+func TestDisplayClockLightsColon(t *testing.T) {
+	drv := mock.New()
+	tm := tm1637.NewWithDriver(drv)
+
+	if err := tm.DisplayClock(12, 34, false); err != nil {
+		t.Fatalf("DisplayClock: %v", err)
+	}
+
+	// [data cmd, addr, grid0, grid1, grid2, grid3, control cmd]
+	bytes := protocolBytes(drv.Writes)
+	if len(bytes) != 7 {
+		t.Fatalf("got %d protocol bytes, want 7: % x", len(bytes), bytes)
+	}
+
+	grid1 := bytes[3]
+	if grid1&0x80 == 0 {
+		t.Errorf("grid-1 byte %#x has the colon bit clear, want it set", grid1)
+	}
+}
+
+func TestDisplayFloatErrorsOnOverflow(t *testing.T) {
+	tm := tm1637.NewWithDriver(mock.New())
+
+	// "1234.5" needs 5 grids ('1','2','3','4.','5') on a default 4-grid
+	// display, so it must error rather than silently drop the leading '1'.
+	if err := tm.DisplayFloat(1234.5, 1); err == nil {
+		t.Fatal("DisplayFloat(1234.5, 1) succeeded, want an overflow error")
+	}
+}
+
+func TestWriteSegmentsAtSkipsControlResend(t *testing.T) {
+	drv := mock.New()
+	tm := tm1637.NewWithDriver(drv)
+
+	if err := tm.SetBrightness(tm1637.DefaultBrightness); err != nil {
+		t.Fatalf("SetBrightness: %v", err)
+	}
+	drv.Writes = nil // only care about writes after the display-control state is in sync
+
+	if err := tm.WriteSegmentsAt(0, []byte{0x3f}); err != nil {
+		t.Fatalf("WriteSegmentsAt: %v", err)
+	}
+	if err := tm.WriteSegmentsAt(0, []byte{0x06}); err != nil {
+		t.Fatalf("WriteSegmentsAt: %v", err)
+	}
+
+	// Each call is [fixed-addr data cmd, addr, segment] with no control
+	// resend, since brightness hasn't changed since SetBrightness above.
+	bytes := protocolBytes(drv.Writes)
+	if len(bytes) != 6 {
+		t.Fatalf("got %d protocol bytes, want 6 (no control resend): % x", len(bytes), bytes)
+	}
+}